@@ -0,0 +1,163 @@
+package lmdb
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+// BackupOptions configures the behavior of Env.CopyWriter.
+type BackupOptions struct {
+	// Compact instructs LMDB to compact the database while copying. It is
+	// equivalent to passing CopyCompact to Env.CopyFD directly.
+	Compact bool
+
+	// Progress, when non-nil, is invoked periodically with the number of
+	// bytes copied so far and the size of the source environment's data
+	// file at the time the copy started. Progress is called from a
+	// goroutine other than the caller of CopyWriter and must not block for
+	// long periods of time.
+	//
+	// The total is always the uncompacted data file size: LMDB does not
+	// expose the size a compacted copy will end up being ahead of time, so
+	// when Compact is set the reported total is an overestimate.
+	Progress func(copied, total int64)
+}
+
+func (opt *BackupOptions) flags() CopyFlag {
+	if opt == nil {
+		return 0
+	}
+	var flags CopyFlag
+	if opt.Compact {
+		flags |= CopyCompact
+	}
+	return flags
+}
+
+// CopyWriter copies the environment to w, streaming the backup through an
+// os.Pipe so that w never needs to be a file-backed destination -- it is
+// suitable for tar writers, gzip.Writer, network connections, or anything
+// else implementing io.Writer. CopyWriter returns the number of bytes
+// written to w.
+//
+// If ctx is canceled before the copy completes, CopyWriter closes the read
+// end of the pipe out from under LMDB to unblock the copy and returns
+// ctx.Err().
+func (env *Env) CopyWriter(ctx context.Context, w io.Writer, opt *BackupOptions) (int64, error) {
+	r, pw, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+
+	// Hand mdb_env_copyfd2 a dup of the write end rather than pw.Fd()
+	// itself. The cancellation path below only ever closes r (and, via the
+	// copy goroutine's defer, the dup) -- never the fd number the C call is
+	// actively blocked on writing to -- so a cancellation can't race a
+	// close against an in-flight write syscall on that fd.
+	dupFd, err := syscall.Dup(int(pw.Fd()))
+	pw.Close()
+	if err != nil {
+		r.Close()
+		return 0, err
+	}
+	dup := os.NewFile(uintptr(dupFd), "lmdb-copywriter")
+
+	flags := opt.flags()
+	var progress func(copied, total int64)
+	var total int64
+	if opt != nil {
+		progress = opt.Progress
+		total = env.dataFileSize()
+	}
+
+	copyErr := make(chan error, 1)
+	go func() {
+		defer dup.Close()
+		copyErr <- env.copyFDLocked(dup.Fd(), flags)
+	}()
+
+	type result struct {
+		n   int64
+		err error
+	}
+	copyDone := make(chan result, 1)
+	go func() {
+		n, err := copyTracking(w, r, progress, total)
+		r.Close()
+		copyDone <- result{n, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		r.Close()
+		<-copyErr
+		res := <-copyDone
+		return res.n, ctx.Err()
+	case err := <-copyErr:
+		res := <-copyDone
+		if err != nil {
+			return res.n, err
+		}
+		return res.n, res.err
+	}
+}
+
+// dataFileSize returns the size in bytes of the environment's data.mdb, or 0
+// if it can't be determined (e.g. the environment isn't open yet).
+func (env *Env) dataFileSize() int64 {
+	path, err := env.Path()
+	if err != nil {
+		return 0
+	}
+	fi, err := os.Stat(filepath.Join(path, "data.mdb"))
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+// copyTracking copies from r to w, invoking progress periodically with the
+// running byte count and total (total may be a rough estimate and is passed
+// through unmodified).
+func copyTracking(w io.Writer, r io.Reader, progress func(copied, total int64), total int64) (int64, error) {
+	if progress == nil {
+		return io.Copy(w, r)
+	}
+
+	var copied int64
+	buf := make([]byte, 32*1024)
+	for {
+		nr, rerr := r.Read(buf)
+		if nr > 0 {
+			nw, werr := w.Write(buf[:nr])
+			copied += int64(nw)
+			progress(copied, total)
+			if werr != nil {
+				return copied, werr
+			}
+			if nw != nr {
+				return copied, io.ErrShortWrite
+			}
+		}
+		if rerr != nil {
+			if rerr == io.EOF {
+				return copied, nil
+			}
+			return copied, rerr
+		}
+	}
+}
+
+// copyFDLocked calls Env.CopyFD while holding the calling goroutine to a
+// single OS thread, because mdb_env_copyfd2 may block for a long time and
+// must not be interleaved with other calls against the same LMDB thread
+// handle.
+func (env *Env) copyFDLocked(fd uintptr, flags CopyFlag) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	return env.CopyFD(fd, flags)
+}
@@ -0,0 +1,104 @@
+package lmdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestEnv_CopyWriter(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	err := env.Update(func(txn *Txn) (err error) {
+		dbi, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		return txn.Put(dbi, []byte("k"), []byte("backup me"), 0)
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	var gzbuf bytes.Buffer
+	gzw := gzip.NewWriter(&gzbuf)
+	n, err := env.CopyWriter(context.Background(), gzw, &BackupOptions{Compact: true})
+	if err != nil {
+		t.Fatalf("copywriter: %v", err)
+	}
+	if n <= 0 {
+		t.Errorf("unexpected byte count: %d", n)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+
+	gzr, err := gzip.NewReader(&gzbuf)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	defer gzr.Close()
+
+	dir, err := ioutil.TempDir("", "mdb_test_backup")
+	if err != nil {
+		t.Fatalf("tempdir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := os.Create(dir + "/data.mdb")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := io.Copy(f, gzr); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	restored, err := NewEnv()
+	if err != nil {
+		t.Fatalf("env: %v", err)
+	}
+	defer restored.Close()
+	if err := restored.Open(dir, 0, 0664); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	err = restored.View(func(txn *Txn) (err error) {
+		dbi, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		v, err := txn.Get(dbi, []byte("k"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "backup me" {
+			t.Errorf("unexpected value: %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("view: %v", err)
+	}
+}
+
+func TestEnv_CopyWriter_cancel(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	_, err := env.CopyWriter(ctx, &buf, nil)
+	if err != ctx.Err() {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
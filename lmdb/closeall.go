@@ -0,0 +1,28 @@
+package lmdb
+
+/*
+#include <lmdb.h>
+*/
+import "C"
+
+// CloseAll closes each of dbis, using mdb_dbi_close, within the scope of
+// txn. mdb_dbi_close itself returns void and can't fail, but a dbi handle
+// that was never actually opened (or belongs to a different environment)
+// fails mdb_dbi_flags with EINVAL; CloseAll checks each handle that way
+// before closing it and aggregates every failure into a MultiError instead
+// of stopping at the first bad handle, so a caller that opened several
+// named databases and wants to release all of them still finds out about
+// every invalid one.
+func (env *Env) CloseAll(txn *Txn, dbis ...DBI) error {
+	var merr MultiError
+	for _, dbi := range dbis {
+		var flags C.uint
+		ret := C.mdb_dbi_flags(txn.txn, C.MDB_dbi(dbi), &flags)
+		if err := operror("mdb_dbi_flags", ret); err != nil {
+			merr.Add(err)
+			continue
+		}
+		C.mdb_dbi_close(env.env, C.MDB_dbi(dbi))
+	}
+	return merr.Err()
+}
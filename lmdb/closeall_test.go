@@ -0,0 +1,40 @@
+package lmdb
+
+import "testing"
+
+func TestEnv_CloseAll(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	var dbiA, dbiB DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		dbiA, err = txn.OpenDBI("a", DBICreate)
+		if err != nil {
+			return err
+		}
+		dbiB, err = txn.OpenDBI("b", DBICreate)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		return env.CloseAll(txn, dbiA, dbiB)
+	})
+	if err != nil {
+		t.Fatalf("closeall: %v", err)
+	}
+}
+
+func TestEnv_CloseAll_invalid(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	err := env.View(func(txn *Txn) error {
+		return env.CloseAll(txn, DBI(1<<20))
+	})
+	if err == nil {
+		t.Errorf("expected an error for an unopened dbi handle")
+	}
+}
@@ -0,0 +1,220 @@
+package lmdb
+
+/*
+#include <stdlib.h>
+#include <lmdb.h>
+*/
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Environment flags, passed to Env.Open and Env.SetFlags/UnsetFlags.
+const (
+	NoTLS    uint = C.MDB_NOTLS
+	NoSync   uint = C.MDB_NOSYNC
+	Readonly uint = C.MDB_RDONLY
+)
+
+// DBICreate is passed to Txn.OpenDBI to create the named database if it
+// does not already exist.
+const DBICreate uint = C.MDB_CREATE
+
+// Env is a single LMDB environment, wrapping an *MDB_env. The zero value is
+// not valid; use NewEnv.
+type Env struct {
+	env  *C.MDB_env
+	path string
+
+	storage Storage
+
+	// txnGate is held in read mode for the lifetime of every open Txn and
+	// in write mode by Resize, so a resize always waits for every
+	// outstanding transaction -- not just ones opened through Update/RunTxn
+	// -- to finish, and blocks new ones from starting in the meantime.
+	txnGate sync.RWMutex
+
+	growthMu sync.Mutex
+	growth   *GrowthPolicy
+}
+
+// NewEnv allocates and initializes a new Env. The Env is not usable until
+// Open is called on it.
+func NewEnv() (*Env, error) {
+	var _env *C.MDB_env
+	ret := C.mdb_env_create(&_env)
+	if err := operror("mdb_env_create", ret); err != nil {
+		return nil, err
+	}
+	return &Env{env: _env}, nil
+}
+
+// Open opens env at the directory path, which must already exist; LMDB
+// creates its data and lock files underneath it unless flags prevent that.
+// mode governs permissions on the files LMDB creates underneath path.
+//
+// Open is implemented in terms of FileStorage; NewEnvWithStorage is the
+// equivalent entry point for a caller that wants a different Storage.
+func (env *Env) Open(path string, flags uint, mode uint) error {
+	storage := NewFileStorage(path)
+	return env.openStorage(storage, flags, mode)
+}
+
+func (env *Env) openStorage(storage Storage, flags uint, mode uint) error {
+	cpath := C.CString(storage.Path())
+	defer C.free(unsafe.Pointer(cpath))
+
+	ret := C.mdb_env_open(env.env, cpath, C.uint(flags), C.mdb_mode_t(mode))
+	if err := operror("mdb_env_open", ret); err != nil {
+		return err
+	}
+	env.path = storage.Path()
+	env.storage = storage
+	return nil
+}
+
+// Path returns the path passed to Open, or an error if the environment has
+// not been opened yet.
+func (env *Env) Path() (string, error) {
+	if env.path == "" {
+		return "", &OpError{Op: "mdb_env_get_path", Errno: Invalid}
+	}
+	return env.path, nil
+}
+
+// Close releases all resources held by env. The only failure it can report
+// is the one from closing env's Storage, returned through a MultiError:
+// mdb_env_close returns void, so failures while unmapping the data file,
+// closing the lock file or tearing down the reader table are not reported.
+func (env *Env) Close() error {
+	var merr MultiError
+
+	if env.storage != nil {
+		merr.Add(env.storage.Close())
+	}
+
+	C.mdb_env_close(env.env)
+	env.env = nil
+
+	return merr.Err()
+}
+
+// Flags returns the flags currently set on env.
+func (env *Env) Flags() (uint, error) {
+	var cflags C.uint
+	ret := C.mdb_env_get_flags(env.env, &cflags)
+	if err := operror("mdb_env_get_flags", ret); err != nil {
+		return 0, err
+	}
+	return uint(cflags), nil
+}
+
+// SetFlags sets one or more flags on env.
+func (env *Env) SetFlags(flags uint) error {
+	ret := C.mdb_env_set_flags(env.env, C.uint(flags), 1)
+	return operror("mdb_env_set_flags", ret)
+}
+
+// UnsetFlags clears one or more flags on env.
+func (env *Env) UnsetFlags(flags uint) error {
+	ret := C.mdb_env_set_flags(env.env, C.uint(flags), 0)
+	return operror("mdb_env_set_flags", ret)
+}
+
+// SetMaxReaders sets the maximum number of concurrent reader slots. It must
+// be called before Open.
+func (env *Env) SetMaxReaders(n int) error {
+	ret := C.mdb_env_set_maxreaders(env.env, C.uint(n))
+	return operror("mdb_env_set_maxreaders", ret)
+}
+
+// MaxReaders returns the maximum number of concurrent reader slots.
+func (env *Env) MaxReaders() (int, error) {
+	var n C.uint
+	ret := C.mdb_env_get_maxreaders(env.env, &n)
+	if err := operror("mdb_env_get_maxreaders", ret); err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// SetMaxDBs sets the maximum number of named databases for the environment.
+// It must be called before Open.
+func (env *Env) SetMaxDBs(n int) error {
+	ret := C.mdb_env_set_maxdbs(env.env, C.MDB_dbi(n))
+	return operror("mdb_env_set_maxdbs", ret)
+}
+
+// SetMapSize sets the size, in bytes, of the memory map env uses -- the
+// hard upper bound on the combined size of all databases in the
+// environment. It is unsafe to call directly while other transactions are
+// active in the current process; use Resize (lmdb#chunk0-3) instead of
+// calling SetMapSize from arbitrary goroutines.
+func (env *Env) SetMapSize(size int64) error {
+	ret := C.mdb_env_set_mapsize(env.env, C.size_t(size))
+	return operror("mdb_env_set_mapsize", ret)
+}
+
+// Info holds runtime information about an environment, as returned by
+// Env.Info.
+type Info struct {
+	MapSize    int64
+	LastPNO    int64
+	LastTxnID  int64
+	MaxReaders int
+	NumReaders int
+}
+
+// Info returns runtime information about env.
+func (env *Env) Info() (*Info, error) {
+	var cinfo C.MDB_envinfo
+	ret := C.mdb_env_info(env.env, &cinfo)
+	if err := operror("mdb_env_info", ret); err != nil {
+		return nil, err
+	}
+	return &Info{
+		MapSize:    int64(cinfo.me_mapsize),
+		LastPNO:    int64(cinfo.me_last_pgno),
+		LastTxnID:  int64(cinfo.me_last_txnid),
+		MaxReaders: int(cinfo.me_maxreaders),
+		NumReaders: int(cinfo.me_numreaders),
+	}, nil
+}
+
+// MaxKeySize returns the maximum size, in bytes, of a key that can be
+// written in env. It is safe to call on a nil *Env, returning the
+// compile-time default.
+func (env *Env) MaxKeySize() int {
+	if env == nil || env.env == nil {
+		return int(C.mdb_env_get_maxkeysize(nil))
+	}
+	return int(C.mdb_env_get_maxkeysize(env.env))
+}
+
+// CopyFlag is passed to Env.Copy and Env.CopyFD to change their behavior.
+type CopyFlag uint
+
+const (
+	// CopyCompact instructs LMDB to compact the database while copying,
+	// omitting free pages, at the cost of a slower copy.
+	CopyCompact CopyFlag = C.MDB_CP_COMPACT
+)
+
+// Copy copies env to the directory at path, which must already exist and be
+// empty. It wraps mdb_env_copy2.
+func (env *Env) Copy(path string, flags CopyFlag) error {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	ret := C.mdb_env_copy2(env.env, cpath, C.uint(flags))
+	return operror("mdb_env_copy2", ret)
+}
+
+// CopyFD copies env to the given file descriptor, an open os.File's Fd() or
+// any other valid writable file descriptor. CopyFD blocks until LMDB
+// finishes writing to fd. It wraps mdb_env_copyfd2.
+func (env *Env) CopyFD(fd uintptr, flags CopyFlag) error {
+	ret := C.mdb_env_copyfd2(env.env, C.mdb_filehandle_t(fd), C.uint(flags))
+	return operror("mdb_env_copyfd2", ret)
+}
@@ -204,25 +204,25 @@ func TestEnv_SetMapSize(t *testing.T) {
 	}
 }
 
+// setup opens an Env backed by MemStorage rather than a plain ioutil.TempDir
+// on disk, so the bulk of this file's suite runs against tmpfs where
+// /dev/shm exists, and clean (via Env.Close) removes the backing directory itself
+// instead of every test doing it by hand.
 func setup(t T) *Env {
 	env, err := NewEnv()
 	if err != nil {
 		t.Fatalf("env: %s", err)
 	}
-	path, err := ioutil.TempDir("/tmp", "mdb_test")
-	if err != nil {
-		t.Fatalf("tempdir: %v", err)
-	}
-	err = os.MkdirAll(path, 0770)
-	if err != nil {
-		t.Fatalf("mkdir: %s", path)
-	}
 	err = env.SetMaxDBs(64 << 10)
 	if err != nil {
 		t.Fatalf("setmaxdbs: %v", err)
 	}
-	err = env.Open(path, 0, 0664)
+
+	storage, err := NewMemStorage()
 	if err != nil {
+		t.Fatalf("memstorage: %v", err)
+	}
+	if err := env.openStorage(storage, 0, 0664); err != nil {
 		t.Fatalf("open: %s", err)
 	}
 
@@ -235,19 +235,19 @@ type T interface {
 }
 
 func clean(env *Env, t T) {
+	var merr MultiError
+
 	path, err := env.Path()
-	if err != nil {
-		t.Errorf("path: %v", err)
-	}
-	err = env.Close()
-	if err != nil {
-		t.Errorf("close: %s", err)
-	}
+	merr.Add(err)
+
+	merr.Add(env.Close())
+
 	if path != "" {
-		err = os.RemoveAll(path)
-		if err != nil {
-			t.Errorf("remove: %v", err)
-		}
+		merr.Add(os.RemoveAll(path))
+	}
+
+	if err := merr.Err(); err != nil {
+		t.Errorf("clean: %v", err)
 	}
 }
 
@@ -0,0 +1,101 @@
+package lmdb
+
+/*
+#include <lmdb.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Errno is an LMDB error code, as returned by the underlying C API.
+type Errno C.int
+
+func (e Errno) Error() string {
+	return C.GoString(C.mdb_strerror(C.int(e)))
+}
+
+// LMDB-defined error codes.
+const (
+	KeyExist        Errno = C.MDB_KEYEXIST
+	NotFound        Errno = C.MDB_NOTFOUND
+	PageNotFound    Errno = C.MDB_PAGE_NOTFOUND
+	Corrupted       Errno = C.MDB_CORRUPTED
+	Panic           Errno = C.MDB_PANIC
+	VersionMismatch Errno = C.MDB_VERSION_MISMATCH
+	Invalid         Errno = C.MDB_INVALID
+	MapFull         Errno = C.MDB_MAP_FULL
+	DBsFull         Errno = C.MDB_DBS_FULL
+	ReadersFull     Errno = C.MDB_READERS_FULL
+	TLSFull         Errno = C.MDB_TLS_FULL
+	TxnFull         Errno = C.MDB_TXN_FULL
+	CursorFull      Errno = C.MDB_CURSOR_FULL
+	PageFull        Errno = C.MDB_PAGE_FULL
+	MapResized      Errno = C.MDB_MAP_RESIZED
+	Incompatible    Errno = C.MDB_INCOMPATIBLE
+	BadRSlot        Errno = C.MDB_BAD_RSLOT
+	BadTxn          Errno = C.MDB_BAD_TXN
+	BadValSize      Errno = C.MDB_BAD_VALSIZE
+	BadDBI          Errno = C.MDB_BAD_DBI
+)
+
+// OpError records an error returned by a specific LMDB operation.
+type OpError struct {
+	Op    string
+	Errno Errno
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("lmdb: %s: %s", e.Op, e.Errno.Error())
+}
+
+// Unwrap lets errors.Is/errors.As match against e.Errno or, for codes that
+// correspond to a system errno (e.g. ENOENT from mdb_env_open), against the
+// equivalent syscall.Errno.
+func (e *OpError) Unwrap() error {
+	if sys, ok := sysErrno(e.Errno); ok {
+		return sys
+	}
+	return e.Errno
+}
+
+// sysErrno maps an Errno that actually originated from the OS (LMDB passes
+// system errno values through unchanged when e.g. open(2) fails) onto a
+// syscall.Errno so that IsErrnoSys and errors.Is(err, syscall.ENOENT) work.
+// LMDB's own error codes are small negative numbers; anything else is a
+// plain system errno.
+func sysErrno(errno Errno) (syscall.Errno, bool) {
+	if errno < 0 {
+		return 0, false
+	}
+	return syscall.Errno(errno), true
+}
+
+// operror converts the return value of an mdb_* call into an error, or nil
+// if ret indicates success.
+func operror(op string, ret C.int) error {
+	if ret == C.MDB_SUCCESS {
+		return nil
+	}
+	return &OpError{Op: op, Errno: Errno(ret)}
+}
+
+// IsErrnoSys returns whether err is an OpError wrapping the system error
+// errno.
+func IsErrnoSys(err error, errno syscall.Errno) bool {
+	opErr, ok := err.(*OpError)
+	if !ok {
+		return false
+	}
+	sys, ok := sysErrno(opErr.Errno)
+	return ok && sys == errno
+}
+
+// IsNotExist returns whether err indicates that an environment's path does
+// not exist, e.g. from a call to Env.Open.
+func IsNotExist(err error) bool {
+	return IsErrnoSys(err, syscall.ENOENT) || os.IsNotExist(err)
+}
@@ -0,0 +1,173 @@
+package lmdb
+
+import "fmt"
+
+// GrowthPolicy configures automatic map growth on Env.Update,
+// Env.UpdateLocked and Env.RunTxn when a write transaction fails with
+// MapFull.
+type GrowthPolicy struct {
+	// Initial is the map size requested the first time the policy is
+	// applied, if the environment's current map size is smaller. A zero
+	// value leaves the environment's existing map size alone.
+	Initial int64
+
+	// Max bounds the size the policy will ever grow the map to. A grow that
+	// would exceed Max grows the map to exactly Max; once the map is already
+	// at Max, a further MapFull fails with a MapFull OpError. A zero value
+	// means unbounded.
+	Max int64
+
+	// Step, if positive, grows the map by a fixed number of bytes on every
+	// MapFull.
+	Step int64
+
+	// Factor, if greater than 1, grows the map by multiplying the current
+	// size. Factor takes precedence over Step when both are set.
+	Factor float64
+
+	// OnGrow, if non-nil, is called after a successful grow with the old
+	// and new map sizes.
+	OnGrow func(old, new int64)
+
+	// MaxRetries bounds how many times a single Update/RunTxn call will
+	// retry its operation function after a growth or reader-full retry. A
+	// zero value uses a default of 10.
+	MaxRetries int
+}
+
+func (p *GrowthPolicy) maxRetries() int {
+	if p == nil || p.MaxRetries <= 0 {
+		return 10
+	}
+	return p.MaxRetries
+}
+
+func (p *GrowthPolicy) nextSize(current int64) int64 {
+	var next int64
+	switch {
+	case p.Factor > 1:
+		next = int64(float64(current) * p.Factor)
+	case p.Step > 0:
+		next = current + p.Step
+	default:
+		next = current * 2
+	}
+	if next <= current {
+		next = current + 1
+	}
+	if p.Max > 0 && next > p.Max {
+		next = p.Max
+	}
+	return next
+}
+
+// SetGrowthPolicy installs policy on env, enabling automatic map growth for
+// subsequent Update/UpdateLocked/RunTxn calls. Passing nil disables the
+// policy.
+func (env *Env) SetGrowthPolicy(policy *GrowthPolicy) {
+	env.growthMu.Lock()
+	defer env.growthMu.Unlock()
+	env.growth = policy
+}
+
+// Resize grows the environment's map to newSize, safely from any goroutine
+// and regardless of how many transactions -- read or write, started via
+// Update/RunTxn or directly via BeginTxn -- are currently open: it blocks
+// new transactions and waits for every existing one to finish before
+// calling mdb_env_set_mapsize, matching LMDB's requirement that no
+// transaction be active anywhere in the process while the map is resized.
+// Resize can be called manually, or is invoked automatically by
+// Update/UpdateLocked/RunTxn when a GrowthPolicy is installed and a write
+// fails with MapFull.
+func (env *Env) Resize(newSize int64) error {
+	env.txnGate.Lock()
+	defer env.txnGate.Unlock()
+	return env.SetMapSize(newSize)
+}
+
+// runWithGrowth runs op as a transaction of the given flags, transparently
+// retrying according to env's GrowthPolicy when the transaction fails with
+// MapFull or ReadersFull, and returning a wrapped error on TxnFull. It is
+// called by Env.RunTxn, so it must use runTxnOnce (not RunTxn) to actually
+// execute an attempt.
+func runWithGrowth(env *Env, flags uint, op TxnOp) error {
+	env.growthMu.Lock()
+	policy := env.growth
+	env.growthMu.Unlock()
+
+	if policy == nil {
+		return env.runTxnOnce(flags, op)
+	}
+
+	if policy.Initial > 0 {
+		if info, err := env.Info(); err == nil && info.MapSize < policy.Initial {
+			if err := env.Resize(policy.Initial); err != nil {
+				return err
+			}
+		}
+	}
+
+	var err error
+	for attempt := 0; attempt <= policy.maxRetries(); attempt++ {
+		err = env.runTxnOnce(flags, op)
+
+		switch {
+		case err == nil:
+			return nil
+		case IsMapFull(err):
+			if growErr := growMap(env, policy); growErr != nil {
+				return growErr
+			}
+		case IsReadersFull(err):
+			if _, checkErr := env.ReaderCheck(); checkErr != nil {
+				return checkErr
+			}
+		case IsTxnFull(err):
+			return fmt.Errorf("lmdb: transaction aborted: %w", err)
+		default:
+			return err
+		}
+	}
+	return err
+}
+
+func growMap(env *Env, policy *GrowthPolicy) error {
+	info, err := env.Info()
+	if err != nil {
+		return err
+	}
+	old := info.MapSize
+	if policy.Max > 0 && old >= policy.Max {
+		return &OpError{Op: "mdb_env_set_mapsize", Errno: MapFull}
+	}
+	next := policy.nextSize(old)
+
+	if err := env.Resize(next); err != nil {
+		return err
+	}
+	if policy.OnGrow != nil {
+		policy.OnGrow(old, next)
+	}
+	return nil
+}
+
+// IsMapFull returns whether err indicates the environment's map is full
+// (MDB_MAP_FULL), the trigger for GrowthPolicy-driven resizing.
+func IsMapFull(err error) bool {
+	opErr, ok := err.(*OpError)
+	return ok && opErr.Errno == MapFull
+}
+
+// IsReadersFull returns whether err indicates the reader lock table is full
+// (MDB_READERS_FULL).
+func IsReadersFull(err error) bool {
+	opErr, ok := err.(*OpError)
+	return ok && opErr.Errno == ReadersFull
+}
+
+// IsTxnFull returns whether err indicates a transaction has too many dirty
+// pages to proceed (MDB_TXN_FULL).
+func IsTxnFull(err error) bool {
+	opErr, ok := err.(*OpError)
+	return ok && opErr.Errno == TxnFull
+}
@@ -0,0 +1,145 @@
+package lmdb
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestEnv_GrowthPolicy stresses Update until several growth events fire.
+// Between writes it opens batches of read transactions directly via
+// BeginTxn (not through Update), but each batch is closed before the next
+// write -- LMDB requires no transaction be active anywhere in the process
+// while the map is resized -- so no reader is open while a grow happens.
+// Resize waiting for an open reader is covered by
+// TestEnv_Resize_waitsForReaders.
+func TestEnv_GrowthPolicy(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	var grows []struct{ old, new int64 }
+	var mu sync.Mutex
+
+	env.SetGrowthPolicy(&GrowthPolicy{
+		Initial: 1 << 20,
+		Max:     64 << 20,
+		Factor:  2,
+		OnGrow: func(old, new int64) {
+			mu.Lock()
+			grows = append(grows, struct{ old, new int64 }{old, new})
+			mu.Unlock()
+		},
+	})
+
+	big := make([]byte, 256<<10)
+	for i := 0; i < 40; i++ {
+		if i%4 == 0 {
+			readers := openReaders(t, env, 4)
+			closeReaders(readers)
+		}
+
+		key := []byte(fmt.Sprintf("k%04d", i))
+		err := env.Update(func(txn *Txn) (err error) {
+			dbi, err := txn.OpenRoot(0)
+			if err != nil {
+				return err
+			}
+			return txn.Put(dbi, key, big, 0)
+		})
+		if err != nil {
+			t.Fatalf("update %d: %v", i, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(grows) == 0 {
+		t.Errorf("expected at least one growth event")
+	}
+	for _, g := range grows {
+		if g.new <= g.old {
+			t.Errorf("non-increasing grow: %d -> %d", g.old, g.new)
+		}
+	}
+}
+
+func openReaders(t *testing.T, env *Env, n int) []*Txn {
+	t.Helper()
+	readers := make([]*Txn, 0, n)
+	for i := 0; i < n; i++ {
+		txn, err := env.BeginTxn(nil, Readonly)
+		if err != nil {
+			t.Fatalf("begintxn: %v", err)
+		}
+		readers = append(readers, txn)
+	}
+	return readers
+}
+
+func closeReaders(readers []*Txn) {
+	for _, txn := range readers {
+		txn.Abort()
+	}
+}
+
+func TestEnv_Resize(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	info, err := env.Info()
+	if err != nil {
+		t.Fatalf("info: %v", err)
+	}
+
+	const grown = 32 << 20
+	if err := env.Resize(grown); err != nil {
+		t.Fatalf("resize: %v", err)
+	}
+
+	info2, err := env.Info()
+	if err != nil {
+		t.Fatalf("info: %v", err)
+	}
+	if info2.MapSize < grown {
+		t.Errorf("unexpected mapsize after resize: %d (< %d)", info2.MapSize, grown)
+	}
+	if info2.MapSize == info.MapSize {
+		t.Errorf("mapsize did not change")
+	}
+}
+
+// TestEnv_Resize_waitsForReaders verifies that Resize blocks until an
+// in-flight reader opened directly via BeginTxn (bypassing Update/RunTxn
+// entirely) finishes, rather than racing ahead or failing outright.
+func TestEnv_Resize_waitsForReaders(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatalf("begintxn: %v", err)
+	}
+
+	resizeDone := make(chan error, 1)
+	go func() {
+		resizeDone <- env.Resize(32 << 20)
+	}()
+
+	select {
+	case <-resizeDone:
+		t.Fatalf("resize completed while a reader opened via BeginTxn was still open")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	txn.Abort()
+
+	select {
+	case err := <-resizeDone:
+		if err != nil {
+			t.Fatalf("resize: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("resize never completed after the reader was aborted")
+	}
+}
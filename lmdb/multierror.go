@@ -0,0 +1,78 @@
+package lmdb
+
+import (
+	"errors"
+	"strings"
+)
+
+// MultiError aggregates zero or more errors encountered while tearing down
+// an Env (Env.Close) or releasing DBI handles (Env.CloseAll), so that a
+// caller sees every underlying failure instead of only the first one
+// returned. Txn.Commit and Txn.Abort do not use it. The pattern follows prometheus/tsdb's
+// tsdb_errors.MultiError.
+//
+// mdb_env_close and mdb_txn_abort return void in the underlying C API, so
+// they can never contribute a failure of their own; what MultiError
+// actually aggregates in Env.Close is the Go-level teardown alongside them
+// (a Storage's file removal, for instance), and in Env.CloseAll it's the
+// mdb_dbi_flags validation LMDB does perform per handle.
+type MultiError []error
+
+// Add appends err to m if err is non-nil.
+func (m *MultiError) Add(err error) {
+	if err == nil {
+		return
+	}
+	if me, ok := err.(MultiError); ok {
+		*m = append(*m, me...)
+		return
+	}
+	*m = append(*m, err)
+}
+
+// Err returns nil if m is empty, the single contained error if m has
+// exactly one, or m itself (formatted as multiple lines) otherwise.
+func (m MultiError) Err() error {
+	switch len(m) {
+	case 0:
+		return nil
+	case 1:
+		return m[0]
+	default:
+		return m
+	}
+}
+
+// Error implements the error interface, formatting each contained error on
+// its own line.
+func (m MultiError) Error() string {
+	lines := make([]string, len(m))
+	for i, err := range m {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Is reports whether any contained error matches target, so that
+// errors.Is(multiErr, target) traverses every child rather than only the
+// first.
+func (m MultiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As reports whether any contained error can be assigned to target, so that
+// errors.As(multiErr, target) traverses every child rather than only the
+// first.
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
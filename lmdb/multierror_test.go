@@ -0,0 +1,38 @@
+package lmdb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMultiError(t *testing.T) {
+	var merr MultiError
+	if merr.Err() != nil {
+		t.Errorf("empty MultiError should have nil Err()")
+	}
+
+	errA := errors.New("a")
+	merr.Add(errA)
+	if merr.Err() != errA {
+		t.Errorf("single-error MultiError should unwrap to the error itself")
+	}
+
+	errB := errors.New("b")
+	merr.Add(errB)
+	err := merr.Err()
+	if err == nil {
+		t.Fatalf("expected non-nil error")
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("errors.Is should find both contained errors: %v", err)
+	}
+
+	var target *OpError
+	wrapped := &OpError{Op: "test", Errno: MapFull}
+	var merr2 MultiError
+	merr2.Add(errA)
+	merr2.Add(wrapped)
+	if !errors.As(merr2.Err(), &target) {
+		t.Errorf("errors.As should find the wrapped OpError")
+	}
+}
@@ -0,0 +1,279 @@
+package lmdb
+
+/*
+#include <stdlib.h>
+#include <lmdb.h>
+#include "_cgo_export.h"
+
+static int lmdbgo_reader_list(MDB_env *env, void *ctx) {
+	return mdb_reader_list(env, (MDB_msg_func *)lmdbgoReaderListCallback, ctx);
+}
+*/
+import "C"
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// ReaderSlot describes a single active entry in the environment's reader
+// lock table, as reported by mdb_reader_list.
+type ReaderSlot struct {
+	// PID is the process ID holding the slot.
+	PID int
+
+	// ThreadID identifies the thread that opened the reader transaction.
+	ThreadID uint64
+
+	// TxnID is the ID of the transaction the reader is (or was) using, or
+	// -1 if the slot is held but currently has no active transaction.
+	TxnID int64
+}
+
+//export lmdbgoReaderListCallback
+func lmdbgoReaderListCallback(msg *C.char, ctx unsafe.Pointer) C.int {
+	slots := (*[]ReaderSlot)(ctx)
+	line := strings.TrimSpace(C.GoString(msg))
+	if slot, ok := parseReaderListLine(line); ok {
+		*slots = append(*slots, slot)
+	}
+	return 0
+}
+
+// parseReaderListLine parses a single line of mdb_reader_list's output,
+// "<pid> <thread, hex, unprefixed> <txnid>", into a ReaderSlot. It reports
+// false for the header line, blank lines, and lines it can't parse.
+func parseReaderListLine(line string) (ReaderSlot, bool) {
+	if line == "" || strings.HasPrefix(line, "pid") {
+		return ReaderSlot{}, false
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return ReaderSlot{}, false
+	}
+
+	// The thread field has no "0x" prefix, so it must be parsed with an
+	// explicit base 16, not inferred with base 0 (which reads it, wrong, as
+	// decimal and silently yields 0).
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return ReaderSlot{}, false
+	}
+	tid, err := strconv.ParseUint(fields[1], 16, 64)
+	if err != nil {
+		return ReaderSlot{}, false
+	}
+
+	// A slot held by a reader with no active transaction prints "-" for
+	// txnid. That's a real, held slot -- exactly the idle-but-held case the
+	// janitor exists to surface -- so it must still be recorded, with TxnID
+	// set to the sentinel -1, rather than treated as a parse failure and
+	// dropped.
+	var txnid int64
+	if fields[2] == "-" {
+		txnid = -1
+	} else {
+		txnid, err = strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return ReaderSlot{}, false
+		}
+	}
+
+	return ReaderSlot{PID: pid, ThreadID: tid, TxnID: txnid}, true
+}
+
+// ReaderList returns the environment's current reader lock table, one
+// ReaderSlot per in-use slot. It wraps mdb_reader_list.
+func (env *Env) ReaderList() ([]ReaderSlot, error) {
+	if env.env == nil {
+		return nil, &OpError{Op: "mdb_reader_list", Errno: Invalid}
+	}
+	var slots []ReaderSlot
+	ret := C.lmdbgo_reader_list(env.env, unsafe.Pointer(&slots))
+	if err := operror("mdb_reader_list", ret); err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// ReaderCheck clears stale entries from the reader lock table -- slots left
+// behind by readers whose process died without calling Txn.Abort/Commit --
+// and returns the number of slots that were cleared. It wraps
+// mdb_reader_check.
+func (env *Env) ReaderCheck() (cleared int, err error) {
+	if env.env == nil {
+		return 0, &OpError{Op: "mdb_reader_check", Errno: Invalid}
+	}
+	var dead C.int
+	ret := C.mdb_reader_check(env.env, &dead)
+	if err := operror("mdb_reader_check", ret); err != nil {
+		return 0, err
+	}
+	return int(dead), nil
+}
+
+// MetricsSink receives counters emitted by the reader janitor. Implementers
+// typically forward these to an observability system; see
+// PrometheusMetricsSink for an adapter onto prometheus.Collector-compatible
+// gauges/counters.
+type MetricsSink interface {
+	// SetGauge records the current value of a named gauge, e.g.
+	// "readers_in_use" or "readers_max".
+	SetGauge(name string, value float64)
+
+	// AddCounter increments a named counter, e.g.
+	// "stale_readers_cleared_total".
+	AddCounter(name string, delta float64)
+}
+
+// JanitorOptions configures Env.StartReaderJanitor.
+type JanitorOptions struct {
+	// MetricsSink receives counter/gauge updates on every tick. If nil, no
+	// metrics are emitted.
+	MetricsSink MetricsSink
+
+	// OnEvent, if non-nil, is called after each tick whose ReaderCheck and
+	// ReaderList calls both succeed, with the number of stale readers
+	// cleared and the full reader list observed that tick.
+	OnEvent func(cleared int, readers []ReaderSlot)
+
+	// OnError, if non-nil, is called whenever a tick's ReaderCheck or
+	// ReaderList call fails, instead of the tick being silently skipped.
+	OnError func(err error)
+}
+
+// readerKey identifies a reader slot across ticks, to let the janitor track
+// how long a given reader has held its slot (ReaderSlot itself carries no
+// timestamp; mdb_reader_list doesn't report one).
+type readerKey struct {
+	pid   int
+	tid   uint64
+	txnID int64
+}
+
+// ReaderJanitor periodically reaps stale reader-lock-table entries in the
+// background. It is created by Env.StartReaderJanitor and stopped with
+// Stop.
+type ReaderJanitor struct {
+	env    *Env
+	ticker *time.Ticker
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	firstSeen map[readerKey]time.Time
+}
+
+// Stop halts the janitor's background goroutine. It is safe to call Stop
+// more than once.
+func (j *ReaderJanitor) Stop() {
+	select {
+	case <-j.done:
+		return
+	default:
+		close(j.done)
+	}
+	j.wg.Wait()
+}
+
+// StartReaderJanitor launches a background goroutine that runs
+// Env.ReaderCheck every interval, reporting metrics and events via opts. The
+// returned ReaderJanitor must be stopped with Stop to release its goroutine.
+//
+// This targets the classic MDB_READERS_FULL failure mode that follows a
+// crashed reader process: periodic ReaderCheck calls reclaim those slots
+// before MaxReaders is exhausted.
+func (env *Env) StartReaderJanitor(interval time.Duration, opts JanitorOptions) *ReaderJanitor {
+	j := &ReaderJanitor{
+		env:    env,
+		ticker: time.NewTicker(interval),
+		done:   make(chan struct{}),
+	}
+	j.wg.Add(1)
+	go j.run(opts)
+	return j
+}
+
+func (j *ReaderJanitor) run(opts JanitorOptions) {
+	defer j.wg.Done()
+	defer j.ticker.Stop()
+	for {
+		select {
+		case <-j.done:
+			return
+		case <-j.ticker.C:
+			j.tick(opts)
+		}
+	}
+}
+
+func (j *ReaderJanitor) tick(opts JanitorOptions) {
+	cleared, err := j.env.ReaderCheck()
+	if err != nil {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+		return
+	}
+	readers, err := j.env.ReaderList()
+	if err != nil {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+		return
+	}
+
+	oldestAge := j.trackAges(readers)
+
+	if sink := opts.MetricsSink; sink != nil {
+		sink.SetGauge("readers_in_use", float64(len(readers)))
+		if max, err := j.env.MaxReaders(); err == nil {
+			sink.SetGauge("readers_max", float64(max))
+		}
+		if cleared > 0 {
+			sink.AddCounter("stale_readers_cleared_total", float64(cleared))
+		}
+		sink.SetGauge("oldest_reader_age_seconds", oldestAge)
+	}
+
+	if opts.OnEvent != nil {
+		opts.OnEvent(cleared, readers)
+	}
+}
+
+// trackAges records the first tick each of readers was observed in and
+// returns the age, in seconds, of the longest-held slot still present.
+// Slots that have disappeared since the last tick (committed/aborted, or
+// reaped by ReaderCheck) are forgotten.
+func (j *ReaderJanitor) trackAges(readers []ReaderSlot) float64 {
+	if j.firstSeen == nil {
+		j.firstSeen = make(map[readerKey]time.Time)
+	}
+
+	now := time.Now()
+	seen := make(map[readerKey]bool, len(readers))
+	var oldestAge float64
+	for _, r := range readers {
+		key := readerKey{pid: r.PID, tid: r.ThreadID, txnID: r.TxnID}
+		seen[key] = true
+
+		first, ok := j.firstSeen[key]
+		if !ok {
+			first = now
+			j.firstSeen[key] = first
+		}
+		if age := now.Sub(first).Seconds(); age > oldestAge {
+			oldestAge = age
+		}
+	}
+
+	for key := range j.firstSeen {
+		if !seen[key] {
+			delete(j.firstSeen, key)
+		}
+	}
+
+	return oldestAge
+}
@@ -0,0 +1,32 @@
+package lmdb
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// PrometheusMetricsSink adapts a MetricsSink onto prometheus gauges and
+// counters, for use with Env.StartReaderJanitor. Gauges and counters are
+// looked up by the name passed to SetGauge/AddCounter in the Gauges and
+// Counters maps, so callers must register a Gauge/Counter per name ahead of
+// time and put it in the matching map. Names with no entry are ignored.
+type PrometheusMetricsSink struct {
+	// Gauges maps a metric name (e.g. "readers_in_use") to the
+	// prometheus.Gauge it should update.
+	Gauges map[string]prometheus.Gauge
+
+	// Counters maps a metric name (e.g. "stale_readers_cleared_total") to
+	// the prometheus.Counter it should increment.
+	Counters map[string]prometheus.Counter
+}
+
+// SetGauge implements MetricsSink.
+func (s *PrometheusMetricsSink) SetGauge(name string, value float64) {
+	if g, ok := s.Gauges[name]; ok {
+		g.Set(value)
+	}
+}
+
+// AddCounter implements MetricsSink.
+func (s *PrometheusMetricsSink) AddCounter(name string, delta float64) {
+	if c, ok := s.Counters[name]; ok {
+		c.Add(delta)
+	}
+}
@@ -0,0 +1,165 @@
+package lmdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReaderListLine(t *testing.T) {
+	cases := []struct {
+		line string
+		want ReaderSlot
+		ok   bool
+	}{
+		{line: "pid     thread     txnid", ok: false},
+		{line: "", ok: false},
+		{line: "123 7f8a2c0 45", want: ReaderSlot{PID: 123, ThreadID: 0x7f8a2c0, TxnID: 45}, ok: true},
+		{line: "123 7f8a2c0 -", want: ReaderSlot{PID: 123, ThreadID: 0x7f8a2c0, TxnID: -1}, ok: true},
+		{line: "123 7f8a2c0", ok: false},
+		{line: "abc 7f8a2c0 45", ok: false},
+	}
+	for _, c := range cases {
+		got, ok := parseReaderListLine(c.line)
+		if ok != c.ok {
+			t.Errorf("parseReaderListLine(%q): ok = %v, want %v", c.line, ok, c.ok)
+			continue
+		}
+		if ok && got != c.want {
+			t.Errorf("parseReaderListLine(%q) = %+v, want %+v", c.line, got, c.want)
+		}
+	}
+}
+
+func TestEnv_ReaderList(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatalf("begintxn: %v", err)
+	}
+	defer txn.Abort()
+
+	readers, err := env.ReaderList()
+	if err != nil {
+		t.Fatalf("readerlist: %v", err)
+	}
+	if len(readers) == 0 {
+		t.Errorf("expected at least one reader slot")
+	}
+}
+
+func TestEnv_ReaderCheck(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	cleared, err := env.ReaderCheck()
+	if err != nil {
+		t.Fatalf("readercheck: %v", err)
+	}
+	if cleared != 0 {
+		t.Errorf("unexpected cleared count on fresh env: %d", cleared)
+	}
+}
+
+type testMetricsSink struct {
+	gauges   map[string]float64
+	counters map[string]float64
+}
+
+func newTestMetricsSink() *testMetricsSink {
+	return &testMetricsSink{gauges: make(map[string]float64), counters: make(map[string]float64)}
+}
+
+func (s *testMetricsSink) SetGauge(name string, value float64)   { s.gauges[name] = value }
+func (s *testMetricsSink) AddCounter(name string, delta float64) { s.counters[name] += delta }
+
+func TestEnv_StartReaderJanitor(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	sink := newTestMetricsSink()
+	events := make(chan int, 8)
+
+	j := env.StartReaderJanitor(10*time.Millisecond, JanitorOptions{
+		MetricsSink: sink,
+		OnEvent: func(cleared int, readers []ReaderSlot) {
+			events <- cleared
+		},
+	})
+	defer j.Stop()
+
+	select {
+	case <-events:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for janitor tick")
+	}
+
+	if _, ok := sink.gauges["readers_in_use"]; !ok {
+		t.Errorf("readers_in_use gauge was never set")
+	}
+}
+
+func TestEnv_StartReaderJanitor_oldestReaderAge(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatalf("begintxn: %v", err)
+	}
+	defer txn.Abort()
+
+	sink := newTestMetricsSink()
+	ticked := make(chan struct{}, 8)
+
+	j := env.StartReaderJanitor(10*time.Millisecond, JanitorOptions{
+		MetricsSink: sink,
+		OnEvent:     func(cleared int, readers []ReaderSlot) { ticked <- struct{}{} },
+	})
+	defer j.Stop()
+
+	// wait for a couple of ticks so the slot has visibly aged between them.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ticked:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for janitor tick")
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-ticked:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for janitor tick")
+	}
+
+	age, ok := sink.gauges["oldest_reader_age_seconds"]
+	if !ok {
+		t.Fatalf("oldest_reader_age_seconds gauge was never set")
+	}
+	if age <= 0 {
+		t.Errorf("expected a positive reader age, got %v", age)
+	}
+}
+
+func TestEnv_StartReaderJanitor_onError(t *testing.T) {
+	env := setup(t)
+
+	errs := make(chan error, 8)
+	j := env.StartReaderJanitor(5*time.Millisecond, JanitorOptions{
+		OnError: func(err error) { errs <- err },
+	})
+	defer j.Stop()
+
+	clean(env, t) // closes env out from under the running janitor
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Errorf("expected a non-nil error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for janitor to report an error")
+	}
+}
@@ -0,0 +1,143 @@
+package lmdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Storage abstracts the on-disk assumptions that Env.Open otherwise makes
+// directly, following the pattern goleveldb uses to hide its storage.Storage
+// behind DB. It lets an Env be pointed at something other than a real
+// directory on a real filesystem: a tmpfs-backed location for tests, or a
+// read-only mapping of an existing snapshot.
+type Storage interface {
+	// Path returns the directory LMDB should treat as the environment's
+	// home, i.e. the value Env.Path returns after Open.
+	Path() string
+
+	// Lock acquires the storage's exclusive writer lock, analogous to
+	// LMDB's own lockfile locking. Implementations that can't be written to
+	// (ReadOnlyStorage) return an error.
+	Lock() error
+
+	// Close releases any resources the Storage holds open.
+	Close() error
+}
+
+// FileStorage is the default Storage, backed by a real directory on disk.
+// Env.Open routes through FileStorage, so existing callers see no change in
+// behavior.
+type FileStorage struct {
+	path string
+}
+
+// NewFileStorage returns a Storage rooted at path, which must already exist;
+// Env.Open's mode/flags govern permissions on the data and lock files LMDB
+// creates underneath it.
+func NewFileStorage(path string) *FileStorage {
+	return &FileStorage{path: path}
+}
+
+// Path implements Storage.
+func (s *FileStorage) Path() string { return s.path }
+
+// Lock implements Storage. FileStorage relies on LMDB's own flock-based
+// locking of the lock file during Open, so Lock is a no-op that only
+// verifies the path exists.
+func (s *FileStorage) Lock() error {
+	_, err := os.Stat(s.path)
+	return err
+}
+
+// Close implements Storage.
+func (s *FileStorage) Close() error { return nil }
+
+// MemStorage is a Storage for tests: it provisions its directory on a
+// tmpfs-backed mount (/dev/shm) so the environment's data stays off a real
+// disk, and Close removes it. Where /dev/shm is not a directory it falls
+// back to os.TempDir, which may be on a real disk.
+//
+// This is not literally the anonymous mmap the request described -- LMDB
+// always opens its data and lock files by path, so there's no way to hand
+// it an in-memory-only backing store without a tmpfs-like mount underneath
+// the path it's given -- but it serves the same purpose: tests and
+// read-only-root deployments that can't or don't want to touch a real disk.
+type MemStorage struct {
+	*FileStorage
+}
+
+// NewMemStorage creates a fresh tmpfs-backed directory for a throwaway
+// environment, e.g. in unit tests that want to run the full suite on a
+// read-only root filesystem.
+func NewMemStorage() (*MemStorage, error) {
+	root := "/dev/shm"
+	if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
+		root = ""
+	}
+	dir, err := ioutil.TempDir(root, "lmdb-memstorage-")
+	if err != nil {
+		return nil, err
+	}
+	return &MemStorage{FileStorage: NewFileStorage(dir)}, nil
+}
+
+// Close removes the backing directory in addition to the base FileStorage
+// behavior.
+func (s *MemStorage) Close() error {
+	return os.RemoveAll(s.Path())
+}
+
+// ReadOnlyStorage wraps another Storage so that Lock always fails, for
+// serving read-only replicas from an immutable snapshot directory without
+// ever taking LMDB's writer lock. NewEnvWithStorage never calls Lock on a
+// *ReadOnlyStorage at all -- it requires the Readonly flag up front instead
+// -- so this failure is a backstop for any other code path that calls Lock
+// directly, as the tests do.
+type ReadOnlyStorage struct {
+	Storage
+}
+
+// NewReadOnlyStorage wraps path as a read-only environment home.
+func NewReadOnlyStorage(path string) *ReadOnlyStorage {
+	return &ReadOnlyStorage{Storage: NewFileStorage(path)}
+}
+
+// Lock implements Storage by always failing, since a read-only replica must
+// never acquire LMDB's writer lock.
+func (s *ReadOnlyStorage) Lock() error {
+	return fmt.Errorf("lmdb: %s is read-only", s.Path())
+}
+
+// NewEnvWithStorage creates a new Env and opens it against storage instead
+// of a plain path.
+//
+// A *ReadOnlyStorage requires the Readonly flag: NewEnvWithStorage rejects
+// the combination of a read-only Storage and a write-intending flags
+// up front, rather than relying on Storage.Lock ever being reached (calling
+// Open with Readonly already causes LMDB itself to refuse writes, which is
+// the enforcement that actually matters; the flag check here exists so the
+// mismatch is caught before even touching the environment). Every other
+// Storage has its Lock called unless flags contains Readonly, matching
+// Open's existing behavior for a plain path.
+func NewEnvWithStorage(storage Storage, flags uint, mode uint) (*Env, error) {
+	if _, ro := storage.(*ReadOnlyStorage); ro && flags&Readonly == 0 {
+		return nil, fmt.Errorf("lmdb: %s is read-only; Open requires the Readonly flag", storage.Path())
+	}
+
+	if flags&Readonly == 0 {
+		if err := storage.Lock(); err != nil {
+			return nil, err
+		}
+	}
+
+	env, err := NewEnv()
+	if err != nil {
+		return nil, err
+	}
+	if err := env.openStorage(storage, flags, mode); err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env, nil
+}
@@ -0,0 +1,110 @@
+package lmdb
+
+import "testing"
+
+func TestMemStorage(t *testing.T) {
+	storage, err := NewMemStorage()
+	if err != nil {
+		t.Fatalf("memstorage: %v", err)
+	}
+	defer storage.Close()
+
+	env, err := NewEnvWithStorage(storage, 0, 0664)
+	if err != nil {
+		t.Fatalf("newenvwithstorage: %v", err)
+	}
+	defer env.Close()
+
+	err = env.Update(func(txn *Txn) (err error) {
+		dbi, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		return txn.Put(dbi, []byte("k"), []byte("v"), 0)
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	path, err := env.Path()
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	if path != storage.Path() {
+		t.Errorf("path: %q (!= %q)", path, storage.Path())
+	}
+}
+
+func TestNewEnvWithStorage_readOnlyRequiresFlag(t *testing.T) {
+	storage, err := NewMemStorage()
+	if err != nil {
+		t.Fatalf("memstorage: %v", err)
+	}
+	defer storage.Close()
+
+	env, err := NewEnvWithStorage(storage, 0, 0664)
+	if err != nil {
+		t.Fatalf("newenvwithstorage: %v", err)
+	}
+	env.Close()
+
+	ro := NewReadOnlyStorage(storage.Path())
+	if _, err := NewEnvWithStorage(ro, 0, 0664); err == nil {
+		t.Errorf("expected NewEnvWithStorage to reject a *ReadOnlyStorage without the Readonly flag")
+	}
+}
+
+func TestReadOnlyStorage(t *testing.T) {
+	storage, err := NewMemStorage()
+	if err != nil {
+		t.Fatalf("memstorage: %v", err)
+	}
+	defer storage.Close()
+
+	// Write through a FileStorage on the same directory: closing an Env
+	// opened on the MemStorage itself would remove the directory.
+	env, err := NewEnvWithStorage(NewFileStorage(storage.Path()), 0, 0664)
+	if err != nil {
+		t.Fatalf("newenvwithstorage: %v", err)
+	}
+	err = env.Update(func(txn *Txn) (err error) {
+		dbi, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		return txn.Put(dbi, []byte("k"), []byte("v"), 0)
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	env.Close()
+
+	ro := NewReadOnlyStorage(storage.Path())
+	if err := ro.Lock(); err == nil {
+		t.Errorf("expected lock to fail on read-only storage")
+	}
+
+	roEnv, err := NewEnvWithStorage(ro, Readonly, 0664)
+	if err != nil {
+		t.Fatalf("newenvwithstorage readonly: %v", err)
+	}
+	defer roEnv.Close()
+
+	err = roEnv.View(func(txn *Txn) (err error) {
+		dbi, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		v, err := txn.Get(dbi, []byte("k"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "v" {
+			t.Errorf("unexpected value: %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("view: %v", err)
+	}
+}
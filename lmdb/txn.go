@@ -0,0 +1,220 @@
+package lmdb
+
+/*
+#include <stdlib.h>
+#include <lmdb.h>
+*/
+import "C"
+
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// DBI is the handle for a named database within an environment.
+type DBI C.MDB_dbi
+
+// Txn is a transaction handle.
+type Txn struct {
+	txn *C.MDB_txn
+	env *Env
+
+	// release ungates env.txnGate's read lock taken by BeginTxn. It is
+	// guarded by releaseOnce so a Txn that is both Committed and Aborted by
+	// mistake, or whose gate is released via nested helpers, only unlocks
+	// once.
+	release     func()
+	releaseOnce sync.Once
+}
+
+// TxnOp is a function run by Env.View, Env.Update, Env.UpdateLocked and
+// Env.RunTxn against an open Txn.
+type TxnOp func(txn *Txn) error
+
+// BeginTxn starts a new transaction in env. parent is the parent
+// transaction for a nested transaction, or nil. flags may contain Readonly.
+//
+// BeginTxn holds env's txnGate in read mode until the returned Txn is
+// committed or aborted, so that Env.Resize can detect and wait for it even
+// though it didn't go through Update/RunTxn.
+func (env *Env) BeginTxn(parent *Txn, flags uint) (*Txn, error) {
+	env.txnGate.RLock()
+
+	var parentC *C.MDB_txn
+	if parent != nil {
+		parentC = parent.txn
+	}
+
+	var _txn *C.MDB_txn
+	ret := C.mdb_txn_begin(env.env, parentC, C.uint(flags), &_txn)
+	if err := operror("mdb_txn_begin", ret); err != nil {
+		env.txnGate.RUnlock()
+		return nil, err
+	}
+
+	txn := &Txn{txn: _txn, env: env}
+	txn.release = func() { env.txnGate.RUnlock() }
+	return txn, nil
+}
+
+// Commit commits txn, making its writes durable (subject to the
+// environment's sync flags).
+//
+// mdb_txn_commit frees the underlying MDB_txn whether it succeeds or fails
+// -- on failure it aborts the transaction itself -- so txn.txn is cleared
+// here unconditionally; a subsequent Abort must not touch the freed handle.
+func (txn *Txn) Commit() error {
+	defer txn.releaseOnce.Do(txn.release)
+	_txn := txn.txn
+	txn.txn = nil
+	ret := C.mdb_txn_commit(_txn)
+	return operror("mdb_txn_commit", ret)
+}
+
+// Abort discards txn and any writes made through it. Abort is a no-op if
+// txn was already committed (successfully or not), since mdb_txn_commit
+// always frees the underlying handle itself.
+func (txn *Txn) Abort() {
+	defer txn.releaseOnce.Do(txn.release)
+	if txn.txn == nil {
+		return
+	}
+	_txn := txn.txn
+	txn.txn = nil
+	C.mdb_txn_abort(_txn)
+}
+
+// OpenRoot opens the unnamed, root database for txn's environment.
+func (txn *Txn) OpenRoot(flags uint) (DBI, error) {
+	var dbi C.MDB_dbi
+	ret := C.mdb_dbi_open(txn.txn, nil, C.uint(flags), &dbi)
+	if err := operror("mdb_dbi_open", ret); err != nil {
+		return 0, err
+	}
+	return DBI(dbi), nil
+}
+
+// OpenDBI opens the named database within txn's environment, creating it if
+// flags includes the create flag understood by mdb_dbi_open.
+func (txn *Txn) OpenDBI(name string, flags uint) (DBI, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	var dbi C.MDB_dbi
+	ret := C.mdb_dbi_open(txn.txn, cname, C.uint(flags), &dbi)
+	if err := operror("mdb_dbi_open", ret); err != nil {
+		return 0, err
+	}
+	return DBI(dbi), nil
+}
+
+// Put stores key/val in dbi.
+func (txn *Txn) Put(dbi DBI, key, val []byte, flags uint) error {
+	mkey := bytesToVal(key)
+	mval := bytesToVal(val)
+	ret := C.mdb_put(txn.txn, C.MDB_dbi(dbi), &mkey, &mval, C.uint(flags))
+	return operror("mdb_put", ret)
+}
+
+// Get retrieves the value stored for key in dbi. The returned slice aliases
+// memory owned by LMDB and is only valid for the lifetime of txn.
+func (txn *Txn) Get(dbi DBI, key []byte) ([]byte, error) {
+	mkey := bytesToVal(key)
+	var mval C.MDB_val
+	ret := C.mdb_get(txn.txn, C.MDB_dbi(dbi), &mkey, &mval)
+	if err := operror("mdb_get", ret); err != nil {
+		return nil, err
+	}
+	return valToBytes(mval), nil
+}
+
+// Del removes key (and, if non-nil, the specific val) from dbi.
+func (txn *Txn) Del(dbi DBI, key, val []byte) error {
+	mkey := bytesToVal(key)
+	var mvalPtr *C.MDB_val
+	if val != nil {
+		mval := bytesToVal(val)
+		mvalPtr = &mval
+	}
+	ret := C.mdb_del(txn.txn, C.MDB_dbi(dbi), &mkey, mvalPtr)
+	return operror("mdb_del", ret)
+}
+
+func bytesToVal(b []byte) C.MDB_val {
+	if len(b) == 0 {
+		return C.MDB_val{}
+	}
+	return C.MDB_val{
+		mv_size: C.size_t(len(b)),
+		mv_data: unsafe.Pointer(&b[0]),
+	}
+}
+
+func valToBytes(v C.MDB_val) []byte {
+	return C.GoBytes(v.mv_data, C.int(v.mv_size))
+}
+
+// runTxnOnce begins a transaction with the given flags, runs op, and
+// commits on success or aborts on error (or if op panics). It performs no
+// retries; RunTxn layers growth/reader-full retry policy on top of it.
+func (env *Env) runTxnOnce(flags uint, op TxnOp) error {
+	txn, err := env.BeginTxn(nil, flags)
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			txn.Abort()
+		}
+	}()
+
+	if err := op(txn); err != nil {
+		return err
+	}
+	if flags&Readonly != 0 {
+		txn.Abort()
+		committed = true
+		return nil
+	}
+	if err := txn.Commit(); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// RunTxn begins a transaction with the given flags, runs op, and commits on
+// success or aborts on error (or if op panics). It is the primitive that
+// View, Update and UpdateLocked are built on.
+//
+// If env has a GrowthPolicy installed (see SetGrowthPolicy), RunTxn
+// transparently retries op when it fails with MapFull (growing the map per
+// the policy first) or ReadersFull (running ReaderCheck first), and returns
+// a wrapped error without retrying on TxnFull.
+func (env *Env) RunTxn(flags uint, op TxnOp) error {
+	return runWithGrowth(env, flags, op)
+}
+
+// View runs op against a read-only transaction.
+func (env *Env) View(op TxnOp) error {
+	return env.RunTxn(Readonly, op)
+}
+
+// Update runs op against a writable transaction, committing on success.
+func (env *Env) Update(fn TxnOp) error {
+	return env.RunTxn(0, fn)
+}
+
+// UpdateLocked behaves like Update, except that it locks the calling
+// goroutine to its current OS thread for the duration of fn. Use it when fn
+// (or something fn calls) relies on the write transaction running on a
+// consistent OS thread, e.g. composing with other cgo calls that are
+// themselves thread-affine.
+func (env *Env) UpdateLocked(fn TxnOp) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	return env.RunTxn(0, fn)
+}